@@ -0,0 +1,20 @@
+package firego
+
+// TokenSource supplies fresh auth tokens for a Firebase reference,
+// analogous to oauth2.TokenSource. Implementations might mint a new
+// Google service-account JWT or refresh a cached custom token.
+type TokenSource interface {
+	// Token returns a valid auth token, or an error if one couldn't be
+	// obtained.
+	Token() (string, error)
+}
+
+// SetTokenSource configures the TokenSource a watch consults to fetch a
+// fresh token once the server reports that the current one has been
+// revoked. The new token is set as the "auth" query parameter and the
+// watch reconnects transparently, emitting an
+// Event{Type: "auth_refreshed"} so callers can observe it. Without a
+// TokenSource, an auth_revoked event terminates the watch.
+func (fb *Firebase) SetTokenSource(ts TokenSource) {
+	fb.tokenSource = ts
+}
@@ -0,0 +1,94 @@
+package firego
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type staticTokenSource struct {
+	token string
+	calls int32
+}
+
+func (ts *staticTokenSource) Token() (string, error) {
+	atomic.AddInt32(&ts.calls, 1)
+	return ts.token, nil
+}
+
+func TestWatchAuthRevokedTerminatesWithoutTokenSource(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeSSEEvent(w, "1", "auth_revoked", `"credential expired"`)
+	}))
+	defer srv.Close()
+
+	fb := New(srv.URL+"/db", srv.Client())
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events, err := fb.WatchContext(ctx)
+	if err != nil {
+		t.Fatalf("WatchContext: %v", err)
+	}
+
+	event := <-events
+	if event.Type != "auth_revoked" {
+		t.Fatalf("got event type %q, want auth_revoked", event.Type)
+	}
+
+	select {
+	case _, ok := <-events:
+		if ok {
+			t.Fatal("expected the channel to close after auth_revoked with no TokenSource")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("watch did not terminate within 1s of auth_revoked with no TokenSource")
+	}
+}
+
+func TestWatchAuthRevokedRefreshesToken(t *testing.T) {
+	var connects int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&connects, 1) == 1 {
+			writeSSEEvent(w, "1", "auth_revoked", `"credential expired"`)
+			return
+		}
+		if got := r.URL.Query().Get("auth"); got != "fresh-token" {
+			t.Errorf("reconnect auth param = %q, want fresh-token", got)
+		}
+		writeSSEEvent(w, "2", "cancel", "null")
+	}))
+	defer srv.Close()
+
+	fb := New(srv.URL+"/db", srv.Client())
+	ts := &staticTokenSource{token: "fresh-token"}
+	fb.SetTokenSource(ts)
+
+	notifications := make(chan Event)
+	if err := fb.Watch(notifications); err != nil {
+		t.Fatalf("Watch: %v", err)
+	}
+
+	var sawRefresh, sawCancel bool
+	for event := range notifications {
+		switch event.Type {
+		case "auth_refreshed":
+			sawRefresh = true
+		case "cancel":
+			sawCancel = true
+		}
+	}
+
+	if !sawRefresh {
+		t.Error("expected an auth_refreshed event")
+	}
+	if !sawCancel {
+		t.Error("expected the watch to end with a cancel event")
+	}
+	if got := atomic.LoadInt32(&ts.calls); got != 1 {
+		t.Errorf("TokenSource.Token called %d times, want 1", got)
+	}
+}
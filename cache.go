@@ -0,0 +1,299 @@
+package firego
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// TreeWatcher maintains a local, in-memory mirror of a Firebase
+// reference by applying the put/patch deltas from its SSE stream to a
+// live tree, so callers don't have to reconstruct current state
+// themselves.
+type TreeWatcher struct {
+	cancel context.CancelFunc
+
+	mu   sync.RWMutex
+	root interface{}
+
+	subsMu sync.Mutex
+	subs   []treeSub
+}
+
+type treeSub struct {
+	path string
+	fn   func(old, new interface{})
+}
+
+// WatchTree starts a TreeWatcher rooted at fb. The returned TreeWatcher
+// consumes the underlying SSE stream internally; call Close when done
+// with it.
+func (fb *Firebase) WatchTree() (*TreeWatcher, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	events, err := fb.WatchContext(ctx)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	tw := &TreeWatcher{cancel: cancel}
+	go tw.run(events)
+	return tw, nil
+}
+
+// Close stops the TreeWatcher's underlying watch.
+func (tw *TreeWatcher) Close() {
+	tw.cancel()
+}
+
+// Get returns the value at path and whether it's present in the tree.
+func (tw *TreeWatcher) Get(path string) (interface{}, bool) {
+	tw.mu.RLock()
+	defer tw.mu.RUnlock()
+	return getPath(tw.root, pathSegments(path))
+}
+
+// Snapshot returns a deep copy of the entire tree as it currently
+// stands.
+func (tw *TreeWatcher) Snapshot() interface{} {
+	tw.mu.RLock()
+	defer tw.mu.RUnlock()
+	return deepCopy(tw.root)
+}
+
+// OnChange registers fn to be called with the old and new value at path
+// whenever a put or patch changes it (or any of its children). fn is
+// called synchronously from the TreeWatcher's internal goroutine, so it
+// should not block.
+func (tw *TreeWatcher) OnChange(path string, fn func(old, new interface{})) {
+	tw.subsMu.Lock()
+	defer tw.subsMu.Unlock()
+	tw.subs = append(tw.subs, treeSub{path: path, fn: fn})
+}
+
+func (tw *TreeWatcher) run(events <-chan Event) {
+	for event := range events {
+		switch event.Type {
+		case "put":
+			tw.applyPut(event.Path, event.Data)
+		case "patch":
+			tw.applyPatch(event.Path, event.Data)
+		}
+	}
+}
+
+// applyPut replaces the subtree at path with data, per Firebase "put"
+// semantics: a put of null deletes the subtree instead.
+func (tw *TreeWatcher) applyPut(path string, data interface{}) {
+	segs := pathSegments(path)
+
+	tw.mu.Lock()
+	oldRoot := deepCopy(tw.root)
+	switch {
+	case len(segs) == 0:
+		tw.root = deepCopy(data)
+	case data == nil:
+		tw.root = deleteAndPrune(tw.root, segs)
+	default:
+		tw.root = setPath(tw.root, segs, deepCopy(data))
+	}
+	newRoot := deepCopy(tw.root)
+	tw.mu.Unlock()
+
+	tw.notify(path, oldRoot, newRoot)
+}
+
+// applyPatch shallow-merges the top-level keys of data into the node at
+// path; keys set to null are removed, pruning now-empty parent maps.
+func (tw *TreeWatcher) applyPatch(path string, data interface{}) {
+	fields, ok := data.(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	segs := pathSegments(path)
+
+	tw.mu.Lock()
+	oldRoot := deepCopy(tw.root)
+
+	node, _ := getPath(tw.root, segs)
+	m, ok := node.(map[string]interface{})
+	if !ok {
+		m = map[string]interface{}{}
+	}
+	for k, v := range fields {
+		if v == nil {
+			delete(m, k)
+		} else {
+			m[k] = deepCopy(v)
+		}
+	}
+
+	if len(m) == 0 {
+		tw.root = deleteAndPrune(tw.root, segs)
+	} else {
+		tw.root = setPath(tw.root, segs, m)
+	}
+	newRoot := deepCopy(tw.root)
+	tw.mu.Unlock()
+
+	tw.notify(path, oldRoot, newRoot)
+}
+
+// notify fires any OnChange subscription whose path is an ancestor of,
+// descendant of, or equal to changedPath, comparing its value in
+// oldRoot and newRoot.
+func (tw *TreeWatcher) notify(changedPath string, oldRoot, newRoot interface{}) {
+	tw.subsMu.Lock()
+	subs := append([]treeSub(nil), tw.subs...)
+	tw.subsMu.Unlock()
+
+	for _, s := range subs {
+		if !pathsRelated(s.path, changedPath) {
+			continue
+		}
+
+		segs := pathSegments(s.path)
+		oldVal, _ := getPath(oldRoot, segs)
+		newVal, _ := getPath(newRoot, segs)
+		if !reflect.DeepEqual(oldVal, newVal) {
+			s.fn(oldVal, newVal)
+		}
+	}
+}
+
+// pathSegments splits a Firebase path like "/a/b/c" into ["a", "b", "c"].
+func pathSegments(path string) []string {
+	var segs []string
+	for _, seg := range strings.Split(path, "/") {
+		if seg != "" {
+			segs = append(segs, seg)
+		}
+	}
+	return segs
+}
+
+// pathsRelated reports whether a and b are the same path or one is an
+// ancestor of the other.
+func pathsRelated(a, b string) bool {
+	as, bs := pathSegments(a), pathSegments(b)
+	n := len(as)
+	if len(bs) < n {
+		n = len(bs)
+	}
+	for i := 0; i < n; i++ {
+		if as[i] != bs[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// getPath walks segs down root, which must be built out of
+// map[string]interface{} nodes as produced by json.Unmarshal.
+func getPath(root interface{}, segs []string) (interface{}, bool) {
+	cur := root
+	for _, seg := range segs {
+		m, ok := cur.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		v, ok := m[seg]
+		if !ok {
+			return nil, false
+		}
+		cur = v
+	}
+	return cur, true
+}
+
+// setPath returns root with value set at segs, creating any
+// intermediate maps that don't yet exist.
+func setPath(root interface{}, segs []string, value interface{}) interface{} {
+	if len(segs) == 0 {
+		return value
+	}
+
+	m, ok := root.(map[string]interface{})
+	if !ok {
+		m = map[string]interface{}{}
+	}
+
+	cur := m
+	for i, seg := range segs {
+		if i == len(segs)-1 {
+			cur[seg] = value
+			break
+		}
+		next, ok := cur[seg].(map[string]interface{})
+		if !ok {
+			next = map[string]interface{}{}
+			cur[seg] = next
+		}
+		cur = next
+	}
+	return m
+}
+
+// deleteAndPrune removes the key at segs from root and prunes any
+// ancestor maps left empty by the deletion.
+func deleteAndPrune(root interface{}, segs []string) interface{} {
+	if len(segs) == 0 {
+		return nil
+	}
+
+	m, ok := root.(map[string]interface{})
+	if !ok {
+		return root
+	}
+
+	// walk down, remembering the chain of maps visited along the path
+	chain := []map[string]interface{}{m}
+	cur := m
+	for _, seg := range segs[:len(segs)-1] {
+		next, ok := cur[seg].(map[string]interface{})
+		if !ok {
+			return root
+		}
+		chain = append(chain, next)
+		cur = next
+	}
+	delete(cur, segs[len(segs)-1])
+
+	// prune now-empty maps walking back up the chain
+	for i := len(chain) - 1; i > 0; i-- {
+		if len(chain[i]) > 0 {
+			break
+		}
+		delete(chain[i-1], segs[i-1])
+	}
+
+	if len(m) == 0 {
+		return nil
+	}
+	return m
+}
+
+// deepCopy recursively copies the map/slice structure produced by
+// json.Unmarshal so callers can't mutate the TreeWatcher's internal
+// state through a Snapshot or a change callback's old/new values.
+func deepCopy(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(t))
+		for k, val := range t {
+			out[k] = deepCopy(val)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(t))
+		for i, val := range t {
+			out[i] = deepCopy(val)
+		}
+		return out
+	default:
+		return v
+	}
+}
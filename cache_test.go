@@ -0,0 +1,97 @@
+package firego
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestTreeWatcherAppliesPutPatchNullDelete(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeSSEEvent(w, "1", "put", `{"path":"/","data":{"a":{"b":1,"c":2}}}`)
+		writeSSEEvent(w, "2", "patch", `{"path":"/a","data":{"b":3,"d":4}}`)
+		writeSSEEvent(w, "3", "put", `{"path":"/a/d","data":null}`)
+		writeSSEEvent(w, "4", "cancel", "null")
+	}))
+	defer srv.Close()
+
+	fb := New(srv.URL+"/db", srv.Client())
+	tw, err := fb.WatchTree()
+	if err != nil {
+		t.Fatalf("WatchTree: %v", err)
+	}
+	defer tw.Close()
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if v, ok := tw.Get("/a/b"); ok && v == float64(3) {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if got, ok := tw.Get("/a/b"); !ok || got != float64(3) {
+		t.Fatalf("Get(/a/b) = %v, %v; want 3, true", got, ok)
+	}
+	if got, ok := tw.Get("/a/c"); !ok || got != float64(2) {
+		t.Fatalf("Get(/a/c) = %v, %v; want 2, true", got, ok)
+	}
+	if _, ok := tw.Get("/a/d"); ok {
+		t.Fatal("Get(/a/d) should be absent after the null put removed it")
+	}
+
+	snapshot, ok := tw.Snapshot().(map[string]interface{})
+	if !ok {
+		t.Fatalf("Snapshot() type = %T, want map[string]interface{}", tw.Snapshot())
+	}
+	a, ok := snapshot["a"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("snapshot[a] type = %T, want map[string]interface{}", snapshot["a"])
+	}
+	if _, ok := a["d"]; ok {
+		t.Error("snapshot should not contain the removed key /a/d")
+	}
+}
+
+func TestTreeWatcherOnChange(t *testing.T) {
+	ready := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-ready
+		writeSSEEvent(w, "1", "put", `{"path":"/","data":{"a":1}}`)
+		writeSSEEvent(w, "2", "put", `{"path":"/a","data":2}`)
+		writeSSEEvent(w, "3", "cancel", "null")
+	}))
+	defer srv.Close()
+
+	fb := New(srv.URL+"/db", srv.Client())
+	tw, err := fb.WatchTree()
+	if err != nil {
+		t.Fatalf("WatchTree: %v", err)
+	}
+	defer tw.Close()
+
+	changes := make(chan [2]interface{}, 4)
+	tw.OnChange("/a", func(old, new interface{}) {
+		changes <- [2]interface{}{old, new}
+	})
+	close(ready)
+
+	select {
+	case change := <-changes:
+		if change[0] != nil || change[1] != float64(1) {
+			t.Fatalf("first change = %v, want [nil 1]", change)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for first OnChange callback")
+	}
+
+	select {
+	case change := <-changes:
+		if change[0] != float64(1) || change[1] != float64(2) {
+			t.Fatalf("second change = %v, want [1 2]", change)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for second OnChange callback")
+	}
+}
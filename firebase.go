@@ -0,0 +1,71 @@
+package firego
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Firebase represents a location in the cloud
+type Firebase struct {
+	url string
+	// paramsMu guards params, which can be read by makeRequest and by
+	// the query builders (when cloning) and written by an in-flight
+	// watch's auth refresh concurrently.
+	paramsMu sync.RWMutex
+	params   url.Values
+	client   *http.Client
+
+	watchMu     sync.Mutex
+	watchCancel context.CancelFunc
+
+	watchBackoffMin    time.Duration
+	watchBackoffMax    time.Duration
+	watchMaxRetries    int
+	watchMaxRetriesSet bool
+
+	tokenSource TokenSource
+}
+
+// New creates a new Firebase reference rooted at the given url
+func New(root string, client *http.Client) *Firebase {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &Firebase{
+		url:    strings.TrimSuffix(root, "/"),
+		params: url.Values{},
+		client: client,
+	}
+}
+
+// makeRequest builds an *http.Request for the given method against fb's
+// location, including any query parameters set on fb
+func (fb *Firebase) makeRequest(method string, body []byte) (*http.Request, error) {
+	reqURL := fmt.Sprintf("%s.json", fb.url)
+	fb.paramsMu.RLock()
+	q := fb.params.Encode()
+	fb.paramsMu.RUnlock()
+	if q != "" {
+		reqURL = fmt.Sprintf("%s?%s", reqURL, q)
+	}
+
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequest(method, reqURL, reader)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-Type", "application/json")
+	return req, nil
+}
@@ -0,0 +1,112 @@
+package firego
+
+import (
+	"encoding/json"
+	"net/url"
+	"strconv"
+)
+
+// OrderBy sets the child key - or one of the special values "$key",
+// "$value", "$priority" - that results should be ordered by for the
+// purposes of LimitToFirst, LimitToLast, StartAt, EndAt, and EqualTo.
+// It returns a new Firebase reference with the parameter applied, so
+// chains like fb.OrderBy("timestamp").LimitToLast(50).Watch(ch) are
+// scoped to the chain and leave fb itself untouched.
+func (fb *Firebase) OrderBy(prop string) *Firebase {
+	clone := fb.clone()
+	clone.setParam("orderBy", prop)
+	return clone
+}
+
+// LimitToFirst restricts results to the first n, ordered by OrderBy.
+func (fb *Firebase) LimitToFirst(n int) *Firebase {
+	clone := fb.clone()
+	clone.params.Set("limitToFirst", strconv.Itoa(n))
+	return clone
+}
+
+// LimitToLast restricts results to the last n, ordered by OrderBy.
+func (fb *Firebase) LimitToLast(n int) *Firebase {
+	clone := fb.clone()
+	clone.params.Set("limitToLast", strconv.Itoa(n))
+	return clone
+}
+
+// StartAt restricts results to those whose OrderBy value is greater
+// than or equal to value.
+func (fb *Firebase) StartAt(value interface{}) *Firebase {
+	clone := fb.clone()
+	clone.setParam("startAt", value)
+	return clone
+}
+
+// EndAt restricts results to those whose OrderBy value is less than or
+// equal to value.
+func (fb *Firebase) EndAt(value interface{}) *Firebase {
+	clone := fb.clone()
+	clone.setParam("endAt", value)
+	return clone
+}
+
+// EqualTo restricts results to those whose OrderBy value equals value.
+func (fb *Firebase) EqualTo(value interface{}) *Firebase {
+	clone := fb.clone()
+	clone.setParam("equalTo", value)
+	return clone
+}
+
+// Shallow limits the depth of data returned: objects are returned with
+// their child keys set to true instead of their actual contents.
+func (fb *Firebase) Shallow(shallow bool) *Firebase {
+	clone := fb.clone()
+	if !shallow {
+		clone.params.Del("shallow")
+		return clone
+	}
+	clone.params.Set("shallow", "true")
+	return clone
+}
+
+// clone returns a new Firebase reference sharing fb's url, client,
+// tokenSource and watch settings but with its own independent copy of
+// the query parameters, so the builder methods above scope their
+// changes to the returned reference instead of mutating fb or any
+// other reference derived from it.
+func (fb *Firebase) clone() *Firebase {
+	fb.paramsMu.RLock()
+	params := make(url.Values, len(fb.params))
+	for k, v := range fb.params {
+		params[k] = append([]string(nil), v...)
+	}
+	fb.paramsMu.RUnlock()
+
+	return &Firebase{
+		url:    fb.url,
+		params: params,
+		client: fb.client,
+
+		watchBackoffMin:    fb.watchBackoffMin,
+		watchBackoffMax:    fb.watchBackoffMax,
+		watchMaxRetries:    fb.watchMaxRetries,
+		watchMaxRetriesSet: fb.watchMaxRetriesSet,
+
+		tokenSource: fb.tokenSource,
+	}
+}
+
+// These query parameters, once set, apply to every request the
+// returned reference makes - including the SSE connection opened by
+// Watch/WatchContext/Subscribe - so the server only pushes deltas
+// matching the query instead of the whole ref.
+
+// setParam JSON-encodes value, since the Firebase REST API expects
+// string values to arrive quoted, and stores it under key. It's only
+// ever called on a freshly cloned, as yet unshared Firebase, so it
+// doesn't need to take paramsMu itself.
+func (fb *Firebase) setParam(key string, value interface{}) {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return
+	}
+	fb.params.Set(key, string(b))
+}
@@ -0,0 +1,47 @@
+package firego
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestQueryBuildersDoNotMutateReceiver(t *testing.T) {
+	fb := New("https://example.firebaseio.com", nil)
+
+	scoped := fb.OrderBy("timestamp").LimitToLast(50)
+
+	if scoped == fb {
+		t.Fatal("OrderBy/LimitToLast should return a new Firebase, not fb itself")
+	}
+	if got := fb.params.Encode(); got != "" {
+		t.Fatalf("fb.params = %q after chaining, want untouched", got)
+	}
+	if got := scoped.params.Get("orderBy"); got != `"timestamp"` {
+		t.Fatalf("scoped orderBy = %q, want %q", got, `"timestamp"`)
+	}
+	if got := scoped.params.Get("limitToLast"); got != "50" {
+		t.Fatalf("scoped limitToLast = %q, want 50", got)
+	}
+}
+
+func TestWatchHonorsQueryParams(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("orderBy"); got != `"timestamp"` {
+			t.Errorf("orderBy query param = %q, want %q", got, `"timestamp"`)
+		}
+		if got := r.URL.Query().Get("limitToLast"); got != "50" {
+			t.Errorf("limitToLast query param = %q, want 50", got)
+		}
+		writeSSEEvent(w, "1", "cancel", "null")
+	}))
+	defer srv.Close()
+
+	fb := New(srv.URL+"/db", srv.Client())
+	notifications := make(chan Event)
+	if err := fb.OrderBy("timestamp").LimitToLast(50).Watch(notifications); err != nil {
+		t.Fatalf("Watch: %v", err)
+	}
+	for range notifications {
+	}
+}
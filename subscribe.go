@@ -0,0 +1,123 @@
+package firego
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// PutEvent is received when the data at Path should be replaced
+// wholesale with Data.
+type PutEvent struct {
+	Path string
+	Data json.RawMessage
+}
+
+// PatchEvent is received when the top-level keys of Data should be
+// shallow-merged into the data at Path.
+type PatchEvent struct {
+	Path string
+	Data json.RawMessage
+}
+
+// KeepAliveEvent is a periodic ping with no payload, sent to keep the
+// underlying connection alive.
+type KeepAliveEvent struct{}
+
+// CancelEvent is received when the Security and Firebase Rules cause a
+// read at the watched location to no longer be allowed.
+type CancelEvent struct{}
+
+// AuthRevokedEvent is received when the auth parameter supplied to the
+// watch is no longer valid and no TokenSource is set; with a TokenSource
+// the token is refreshed and the watch reconnects instead.
+type AuthRevokedEvent struct {
+	Reason string
+}
+
+// ErrorEvent is received when an event couldn't be parsed, or a fresh
+// token couldn't be obtained from the TokenSource. Connection errors are
+// not reported here; they trigger a reconnect instead.
+type ErrorEvent struct {
+	Err error
+}
+
+// EventHandler reacts to the typed events produced by Subscribe.
+// Implementations should embed DefaultHandler to avoid having to
+// implement methods they don't care about.
+type EventHandler interface {
+	OnPut(PutEvent)
+	OnPatch(PatchEvent)
+	OnCancel(CancelEvent)
+	OnAuthRevoked(AuthRevokedEvent)
+	OnError(ErrorEvent)
+}
+
+// DefaultHandler is a no-op EventHandler meant to be embedded by callers
+// that only want to override a subset of its methods.
+type DefaultHandler struct{}
+
+// OnPut is a no-op.
+func (DefaultHandler) OnPut(PutEvent) {}
+
+// OnPatch is a no-op.
+func (DefaultHandler) OnPatch(PatchEvent) {}
+
+// OnCancel is a no-op.
+func (DefaultHandler) OnCancel(CancelEvent) {}
+
+// OnAuthRevoked is a no-op.
+func (DefaultHandler) OnAuthRevoked(AuthRevokedEvent) {}
+
+// OnError is a no-op.
+func (DefaultHandler) OnError(ErrorEvent) {}
+
+// Subscribe watches fb and dispatches each event received to the
+// matching method on handler, so callers can decode Data directly into
+// their own structs instead of type-asserting a map[string]interface{}.
+// It returns a CancelFunc that stops the subscription; keep-alive and
+// reconnect notifications are consumed internally and have no
+// corresponding handler method.
+func (fb *Firebase) Subscribe(handler EventHandler) (context.CancelFunc, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	events, err := fb.WatchContext(ctx)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	go func() {
+		for event := range events {
+			dispatchEvent(handler, event)
+		}
+	}()
+
+	return cancel, nil
+}
+
+func dispatchEvent(handler EventHandler, event Event) {
+	switch event.Type {
+	case "put":
+		handler.OnPut(PutEvent{Path: event.Path, Data: toRawMessage(event.Data)})
+	case "patch":
+		handler.OnPatch(PatchEvent{Path: event.Path, Data: toRawMessage(event.Data)})
+	case "cancel":
+		handler.OnCancel(CancelEvent{})
+	case "auth_revoked":
+		reason, _ := event.Data.(string)
+		handler.OnAuthRevoked(AuthRevokedEvent{Reason: reason})
+	case "error":
+		err, _ := event.Data.(error)
+		handler.OnError(ErrorEvent{Err: err})
+	}
+}
+
+// toRawMessage re-encodes an already-decoded event payload back into
+// JSON so handlers can unmarshal it into their own types.
+func toRawMessage(data interface{}) json.RawMessage {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil
+	}
+	return raw
+}
@@ -0,0 +1,68 @@
+package firego
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type recordingHandler struct {
+	DefaultHandler
+	puts    []PutEvent
+	patches []PatchEvent
+	done    chan struct{}
+}
+
+func (h *recordingHandler) OnPut(e PutEvent) {
+	h.puts = append(h.puts, e)
+}
+
+func (h *recordingHandler) OnPatch(e PatchEvent) {
+	h.patches = append(h.patches, e)
+}
+
+func (h *recordingHandler) OnCancel(CancelEvent) {
+	close(h.done)
+}
+
+func TestSubscribeDispatchesTypedEvents(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeSSEEvent(w, "1", "put", `{"path":"/","data":{"a":1}}`)
+		writeSSEEvent(w, "2", "patch", `{"path":"/a","data":{"b":2}}`)
+		writeSSEEvent(w, "3", "cancel", "null")
+	}))
+	defer srv.Close()
+
+	fb := New(srv.URL+"/db", srv.Client())
+	handler := &recordingHandler{done: make(chan struct{})}
+
+	cancel, err := fb.Subscribe(handler)
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	defer cancel()
+
+	select {
+	case <-handler.done:
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for OnCancel")
+	}
+
+	if len(handler.puts) != 1 || handler.puts[0].Path != "/" {
+		t.Fatalf("puts = %+v, want one PutEvent at /", handler.puts)
+	}
+	var putData map[string]int
+	if err := json.Unmarshal(handler.puts[0].Data, &putData); err != nil || putData["a"] != 1 {
+		t.Errorf("put data = %s, err %v", handler.puts[0].Data, err)
+	}
+
+	if len(handler.patches) != 1 || handler.patches[0].Path != "/a" {
+		t.Fatalf("patches = %+v, want one PatchEvent at /a", handler.patches)
+	}
+	var patchData map[string]int
+	if err := json.Unmarshal(handler.patches[0].Data, &patchData); err != nil || patchData["b"] != 2 {
+		t.Errorf("patch data = %s, err %v", handler.patches[0].Data, err)
+	}
+}
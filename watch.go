@@ -2,9 +2,13 @@ package firego
 
 import (
 	"bufio"
+	"context"
 	"encoding/json"
-	"log"
+	"fmt"
+	"math/rand"
+	"net/http"
 	"strings"
+	"time"
 )
 
 // Event represents a notification received when watching a
@@ -18,112 +22,338 @@ type Event struct {
 	Data interface{}
 }
 
-// StopWatching stops tears down all connections that are watching
-func (fb *Firebase) StopWatching() {
-	if fb.watching {
-		// signal connection to terminal
-		fb.stopWatching <- struct{}{}
-		// flip the bit back to not watching
-		fb.watching = false
+const (
+	defaultWatchBackoffMin = 1 * time.Second
+	defaultWatchBackoffMax = 30 * time.Second
+)
+
+// SetWatchBackoff configures the min and max delay used between
+// reconnection attempts after a watch's connection drops. The delay
+// doubles on each consecutive failed attempt, up to max, with up to
+// 50% jitter added on top to avoid a thundering herd of reconnects.
+// It defaults to 1s/30s.
+func (fb *Firebase) SetWatchBackoff(min, max time.Duration) {
+	fb.watchBackoffMin = min
+	fb.watchBackoffMax = max
+}
+
+// SetWatchMaxRetries configures how many consecutive reconnection
+// attempts a watch makes before giving up and closing its channel. A
+// negative value retries forever. It defaults to -1.
+func (fb *Firebase) SetWatchMaxRetries(n int) {
+	fb.watchMaxRetries = n
+	fb.watchMaxRetriesSet = true
+}
+
+// WatchContext listens for changes on a firebase reference and returns a
+// channel of Events. Unlike Watch, any number of WatchContext calls can
+// be active on the same Firebase reference at once, each with its own
+// independent connection and channel. The watch runs until ctx is
+// canceled, at which point the underlying connection is closed, the
+// goroutine is drained, and the returned channel is closed.
+//
+// Malformed SSE payloads are delivered as Event{Type: "error", Data: err}
+// rather than killing the process, and the connection reconnects
+// automatically on transport errors; see Watch for those semantics.
+func (fb *Firebase) WatchContext(ctx context.Context) (<-chan Event, error) {
+	// validate the request can be built before starting the goroutine
+	if _, err := fb.makeRequest("GET", nil); err != nil {
+		return nil, err
 	}
+
+	notifications := make(chan Event)
+	go fb.watchLoop(ctx, notifications)
+	return notifications, nil
 }
 
 // Watch listens for changes on a firebase instance and
 // passes over to the given chan.
 //
+// The underlying connection reconnects automatically with exponential
+// backoff and jitter whenever it's dropped by a transport error, an
+// EOF, or a non-2xx response, resending the id of the last event seen
+// as the SSE Last-Event-ID header so the server can resume the stream
+// where it left off. Every time this happens an Event{Type: "reconnect"}
+// is sent on notifications so callers can tell a transient network blip
+// apart from a real "cancel"/"auth_revoked" termination.
+//
 // Only one connection can be established at a time. The
 // second call to this function without a call to fb.StopWatching
 // will close the channel given and return nil immediately
+//
+// Watch is a thin wrapper around WatchContext for callers that don't
+// need cancellation or concurrent watches; new code should prefer
+// WatchContext.
 func (fb *Firebase) Watch(notifications chan Event) error {
-	if fb.watching {
+	fb.watchMu.Lock()
+	if fb.watchCancel != nil {
+		fb.watchMu.Unlock()
 		close(notifications)
 		return nil
 	}
+	ctx, cancel := context.WithCancel(context.Background())
+	fb.watchCancel = cancel
+	fb.watchMu.Unlock()
 
-	// build SSE request
-	req, err := fb.makeRequest("GET", nil)
+	events, err := fb.WatchContext(ctx)
 	if err != nil {
+		cancel()
+		fb.watchMu.Lock()
+		fb.watchCancel = nil
+		fb.watchMu.Unlock()
 		return err
 	}
-	req.Header.Add("Accept", "text/event-stream")
 
-	// do request
+	go func() {
+		for event := range events {
+			notifications <- event
+		}
+		close(notifications)
+
+		fb.watchMu.Lock()
+		fb.watchCancel = nil
+		fb.watchMu.Unlock()
+	}()
+	return nil
+}
+
+// StopWatching tears down the connection started by Watch, if any.
+func (fb *Firebase) StopWatching() {
+	fb.watchMu.Lock()
+	cancel := fb.watchCancel
+	fb.watchMu.Unlock()
+
+	if cancel != nil {
+		cancel()
+	}
+}
+
+// watchLoop owns the connect/read/backoff cycle for a single watch.
+func (fb *Firebase) watchLoop(ctx context.Context, notifications chan Event) {
+	defer close(notifications)
+
+	backoffMin := fb.watchBackoffMin
+	if backoffMin == 0 {
+		backoffMin = defaultWatchBackoffMin
+	}
+	backoffMax := fb.watchBackoffMax
+	if backoffMax == 0 {
+		backoffMax = defaultWatchBackoffMax
+	}
+	maxRetries := -1
+	if fb.watchMaxRetriesSet {
+		maxRetries = fb.watchMaxRetries
+	}
+
+	var lastEventID string
+	attempt := 0
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		resp, err := fb.dialWatch(ctx, lastEventID)
+		if err == nil {
+			var terminated, immediate bool
+			terminated, immediate, err = fb.readEvents(ctx, resp, notifications, &lastEventID)
+			if terminated {
+				return
+			}
+			if immediate {
+				// e.g. an auth token refresh - reconnect right away,
+				// without counting against the backoff/retry budget
+				attempt = 0
+				continue
+			}
+			attempt = 0
+		}
+
+		// an error caused by ctx being canceled isn't a dropped connection
+		if ctx.Err() != nil {
+			return
+		}
+
+		attempt++
+		if maxRetries >= 0 && attempt > maxRetries {
+			return
+		}
+
+		delay := watchBackoff(attempt, backoffMin, backoffMax)
+		select {
+		case notifications <- Event{Type: "reconnect", Data: err}:
+		case <-ctx.Done():
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+	}
+}
+
+// dialWatch opens a new SSE connection, resuming from lastEventID if set.
+func (fb *Firebase) dialWatch(ctx context.Context, lastEventID string) (*http.Response, error) {
+	req, err := fb.makeRequest("GET", nil)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Accept", "text/event-stream")
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+
 	resp, err := fb.client.Do(req)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		resp.Body.Close()
+		return nil, fmt.Errorf("firego: watch request failed with status %s", resp.Status)
 	}
+	return resp, nil
+}
+
+// readEvents consumes SSE events off resp.Body until the stream ends,
+// errors, ctx is canceled, or a "cancel" event is received, updating
+// *lastEventID as "id:" fields are seen so the caller can resume on
+// reconnect. immediate reports that the caller should reconnect right
+// away rather than backing off, e.g. after a successful auth refresh.
+func (fb *Firebase) readEvents(ctx context.Context, resp *http.Response, notifications chan Event, lastEventID *string) (terminated, immediate bool, err error) {
+	// build scanner for response body
+	scanner := bufio.NewScanner(resp.Body)
+	// set custom split function for SSE events
+	scanner.Split(eventSplit)
 
-	// set watching flag
-	fb.watching = true
+	done := make(chan struct{})
+	defer close(done)
 
-	// start parsing response body
+	// close the response body as soon as ctx is canceled
 	go func() {
-		// build scanner for response body
-		scanner := bufio.NewScanner(resp.Body)
-		// set custom split function for SSE events
-		scanner.Split(eventSplit)
-
-		// monitor the stopWatching channel
-		// if we're told to stop, close the response Body
-		go func() {
-			<-fb.stopWatching
+		select {
+		case <-ctx.Done():
 			resp.Body.Close()
-		}()
-	scanning:
-		for scanner.Scan() {
-			// split event string
-			// 		event: put
-			// 		data: {"path":"/","data":{"foo":"bar"}}
-			parts := strings.Split(scanner.Text(), "\n")
-
-			// create a base event
-			event := Event{
-				Type: strings.Replace(parts[0], "event: ", "", 1),
+		case <-done:
+		}
+	}()
+
+	for scanner.Scan() {
+		// split event string, e.g.
+		// 		id: 1234
+		// 		event: put
+		// 		data: {"path":"/","data":{"foo":"bar"}}
+		lines := strings.Split(scanner.Text(), "\n")
+
+		event := Event{}
+		var data string
+		for _, line := range lines {
+			switch {
+			case strings.HasPrefix(line, "event: "):
+				event.Type = strings.Replace(line, "event: ", "", 1)
+			case strings.HasPrefix(line, "data: "):
+				data = strings.Replace(line, "data: ", "", 1)
+			case strings.HasPrefix(line, "id: "):
+				*lastEventID = strings.Replace(line, "id: ", "", 1)
 			}
+		}
 
-			// should be reacting differently based off the type of event
-			switch event.Type {
-			case "put", "patch": // we've got extra data we've got to parse
+		// should be reacting differently based off the type of event
+		switch event.Type {
+		case "put", "patch": // we've got extra data we've got to parse
 
-				// the extra data is in json format
-				var data map[string]interface{}
-				if err := json.Unmarshal([]byte(strings.Replace(parts[1], "data: ", "", 1)), &data); err != nil {
-					log.Fatal(err)
+			// the extra data is in json format
+			var payload map[string]interface{}
+			if err := json.Unmarshal([]byte(data), &payload); err != nil {
+				// malformed payloads are surfaced to the caller instead
+				// of killing the process
+				if !sendEvent(ctx, notifications, Event{Type: "error", Data: err}) {
+					resp.Body.Close()
+					return true, false, nil
 				}
+				continue
+			}
+
+			// set the extra fields
+			event.Path, _ = payload["path"].(string)
+			event.Data = payload["data"]
 
-				// set the extra fields
-				event.Path = data["path"].(string)
-				event.Data = data["data"]
-
-				// ship it
-				notifications <- event
-			case "keep-alive":
-				// received ping - nothing to do here
-			case "cancel":
-				// The data for this event is null
-				// This event will be sent if the Security and Firebase Rules
-				// cause a read at the requested location to no longer be allowed
-
-				// send the cancel event
-				notifications <- event
-				break scanning
-			case "auth_revoked":
-				// The data for this event is a string indicating that a the credential has expired
-				// This event will be sent when the supplied auth parameter is no longer valid
-
-				// TODO: handle
+			// ship it
+			if !sendEvent(ctx, notifications, event) {
+				resp.Body.Close()
+				return true, false, nil
 			}
-		}
+		case "keep-alive":
+			// received ping - nothing to do here
+		case "cancel":
+			// The data for this event is null
+			// This event will be sent if the Security and Firebase Rules
+			// cause a read at the requested location to no longer be allowed
 
-		// call stop watching to reset state and cleanup routines
-		fb.StopWatching()
-		close(notifications)
+			// send the cancel event
+			sendEvent(ctx, notifications, event)
+			resp.Body.Close()
+			return true, false, nil
+		case "auth_revoked":
+			// The data for this event is a string indicating that the
+			// credential has expired. This event will be sent when the
+			// supplied auth parameter is no longer valid.
+			var reason string
+			json.Unmarshal([]byte(data), &reason)
+			event.Data = reason
 
-		if err := scanner.Err(); err != nil {
-			log.Printf("Error: %s\n", err)
+			if fb.tokenSource == nil {
+				// nothing we can do to recover - surface it and terminate
+				// the watch; the server will have torn it down anyway
+				sendEvent(ctx, notifications, event)
+				resp.Body.Close()
+				return true, false, fmt.Errorf("firego: auth revoked: %s", reason)
+			}
+
+			token, terr := fb.tokenSource.Token()
+			if terr != nil {
+				sendEvent(ctx, notifications, Event{Type: "error", Data: terr})
+				resp.Body.Close()
+				return false, false, terr
+			}
+
+			fb.paramsMu.Lock()
+			fb.params.Set("auth", token)
+			fb.paramsMu.Unlock()
+			sendEvent(ctx, notifications, Event{Type: "auth_refreshed"})
+			resp.Body.Close()
+			return false, true, nil
 		}
-	}()
-	return nil
+	}
+
+	resp.Body.Close()
+	return false, false, scanner.Err()
+}
+
+// sendEvent delivers event on notifications, returning false instead of
+// blocking forever if ctx is canceled first.
+func sendEvent(ctx context.Context, notifications chan Event, event Event) bool {
+	select {
+	case notifications <- event:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// watchBackoff returns the delay to wait before the given reconnect
+// attempt (1-indexed), doubling from min up to max and adding up to 50%
+// jitter so that many watchers don't reconnect in lockstep.
+func watchBackoff(attempt int, min, max time.Duration) time.Duration {
+	delay := min << uint(attempt-1)
+	if delay <= 0 || delay > max {
+		delay = max
+	}
+	jitter := time.Duration(rand.Int63n(int64(delay)/2 + 1))
+	return delay + jitter
 }
 
 func eventSplit(data []byte, atEOF bool) (int, []byte, error) {
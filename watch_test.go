@@ -0,0 +1,169 @@
+package firego
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// writeSSEEvent writes a single SSE event frame and flushes it, so
+// tests can drip-feed events to a watch without buffering.
+func writeSSEEvent(w http.ResponseWriter, id, event, data string) {
+	fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", id, event, data)
+	if f, ok := w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func TestWatchBackoffBounds(t *testing.T) {
+	min := 10 * time.Millisecond
+	max := 100 * time.Millisecond
+
+	for attempt := 1; attempt <= 10; attempt++ {
+		d := watchBackoff(attempt, min, max)
+		if d < min || d > max+max/2 {
+			t.Fatalf("attempt %d: backoff %v out of expected bounds [%v, %v]", attempt, d, min, max+max/2)
+		}
+	}
+}
+
+func TestWatchReconnectsAndResumesLastEventID(t *testing.T) {
+	var connects int32
+	var lastEventIDHeader atomic.Value
+	lastEventIDHeader.Store("")
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&connects, 1)
+		lastEventIDHeader.Store(r.Header.Get("Last-Event-ID"))
+
+		if n == 1 {
+			// drop the connection after one event to force a reconnect
+			writeSSEEvent(w, "1", "put", `{"path":"/","data":{"a":1}}`)
+			return
+		}
+
+		// the reconnect should carry the id of the last event seen
+		writeSSEEvent(w, "2", "cancel", "null")
+	}))
+	defer srv.Close()
+
+	// use a path under the server URL: makeRequest appends ".json"
+	// straight onto fb.url, which would otherwise land in the port.
+	fb := New(srv.URL+"/db", srv.Client())
+	fb.SetWatchBackoff(time.Millisecond, 5*time.Millisecond)
+
+	notifications := make(chan Event)
+	if err := fb.Watch(notifications); err != nil {
+		t.Fatalf("Watch: %v", err)
+	}
+
+	var sawReconnect, sawCancel bool
+	for event := range notifications {
+		switch event.Type {
+		case "reconnect":
+			sawReconnect = true
+		case "cancel":
+			sawCancel = true
+		}
+	}
+
+	if !sawReconnect {
+		t.Error("expected a reconnect event after the first connection dropped")
+	}
+	if !sawCancel {
+		t.Error("expected the watch to end with a cancel event")
+	}
+	if got := lastEventIDHeader.Load().(string); got != "1" {
+		t.Errorf("Last-Event-ID on reconnect = %q, want %q", got, "1")
+	}
+}
+
+func TestWatchContextCancelClosesChannel(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// keep the connection open until the client goes away
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+
+	fb := New(srv.URL+"/db", srv.Client())
+	ctx, cancel := context.WithCancel(context.Background())
+
+	events, err := fb.WatchContext(ctx)
+	if err != nil {
+		t.Fatalf("WatchContext: %v", err)
+	}
+
+	cancel()
+
+	select {
+	case _, ok := <-events:
+		if ok {
+			t.Fatal("expected channel to be closed after cancellation, got an event instead")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("channel was not closed within 1s of cancellation")
+	}
+}
+
+func TestWatchContextConcurrentWatchers(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeSSEEvent(w, "1", "cancel", "null")
+	}))
+	defer srv.Close()
+
+	fb := New(srv.URL+"/db", srv.Client())
+
+	ctx1, cancel1 := context.WithCancel(context.Background())
+	defer cancel1()
+	ctx2, cancel2 := context.WithCancel(context.Background())
+	defer cancel2()
+
+	events1, err := fb.WatchContext(ctx1)
+	if err != nil {
+		t.Fatalf("WatchContext 1: %v", err)
+	}
+	events2, err := fb.WatchContext(ctx2)
+	if err != nil {
+		t.Fatalf("WatchContext 2: %v", err)
+	}
+
+	for i, events := range []<-chan Event{events1, events2} {
+		select {
+		case event := <-events:
+			if event.Type != "cancel" {
+				t.Errorf("watcher %d: got event type %q, want cancel", i, event.Type)
+			}
+		case <-time.After(time.Second):
+			t.Errorf("watcher %d: timed out waiting for cancel event", i)
+		}
+	}
+}
+
+func TestWatchContextMalformedPayloadSurfacesError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeSSEEvent(w, "1", "put", `not json`)
+		writeSSEEvent(w, "2", "cancel", "null")
+	}))
+	defer srv.Close()
+
+	fb := New(srv.URL+"/db", srv.Client())
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events, err := fb.WatchContext(ctx)
+	if err != nil {
+		t.Fatalf("WatchContext: %v", err)
+	}
+
+	event := <-events
+	if event.Type != "error" {
+		t.Fatalf("got event type %q, want error", event.Type)
+	}
+	if _, ok := event.Data.(error); !ok {
+		t.Errorf("error event Data is %T, want error", event.Data)
+	}
+}